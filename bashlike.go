@@ -2,12 +2,12 @@ package bashlike
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -31,32 +31,36 @@ func Echo(args ...interface{}) error {
 }
 
 // Cat reads a file and returns its content as a string.
-func Cat(filename string) (string, error) {
-	content, err := os.ReadFile(filename)
+func Cat(filename string) (string, error) { return NewShell(DefaultFS).Cat(filename) }
+
+// Cat reads a file and returns its content as a string.
+func (s *Shell) Cat(filename string) (string, error) {
+	content, err := s.FS.ReadFile(filename)
 	if err != nil {
 		return "", fmt.Errorf("%w: %v", ErrIO, err)
 	}
 	return string(content), nil
 }
 
-// Grep searches for a pattern in a string and returns matching lines.
+// Grep searches for a pattern in a string and returns matching lines. It is
+// a thin wrapper around the streaming GrepReader.
 func Grep(pattern, text string) ([]string, error) {
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInvalidRegex, err)
+	var buf bytes.Buffer
+	if err := GrepReader(pattern, strings.NewReader(text), &buf); err != nil {
+		return nil, err
 	}
-	var matches []string
-	for _, line := range strings.Split(text, "\n") {
-		if re.MatchString(line) {
-			matches = append(matches, line)
-		}
+	if buf.Len() == 0 {
+		return nil, nil
 	}
-	return matches, nil
+	return strings.Split(buf.String(), "\n"), nil
 }
 
 // Ls lists files in a directory.
-func Ls(dir string) ([]string, error) {
-	files, err := os.ReadDir(dir)
+func Ls(dir string) ([]string, error) { return NewShell(DefaultFS).Ls(dir) }
+
+// Ls lists files in a directory.
+func (s *Shell) Ls(dir string) ([]string, error) {
+	files, err := s.FS.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrIO, err)
 	}
@@ -68,8 +72,11 @@ func Ls(dir string) ([]string, error) {
 }
 
 // Mkdir creates a directory.
-func Mkdir(path string) error {
-	err := os.MkdirAll(path, 0755)
+func Mkdir(path string) error { return NewShell(DefaultFS).Mkdir(path) }
+
+// Mkdir creates a directory.
+func (s *Shell) Mkdir(path string) error {
+	err := s.FS.MkdirAll(path, 0755)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrIO, err)
 	}
@@ -77,8 +84,11 @@ func Mkdir(path string) error {
 }
 
 // Rm removes a file or directory.
-func Rm(path string) error {
-	err := os.RemoveAll(path)
+func Rm(path string) error { return NewShell(DefaultFS).Rm(path) }
+
+// Rm removes a file or directory.
+func (s *Shell) Rm(path string) error {
+	err := s.FS.RemoveAll(path)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrIO, err)
 	}
@@ -86,8 +96,11 @@ func Rm(path string) error {
 }
 
 // Pwd returns the current working directory.
-func Pwd() (string, error) {
-	dir, err := os.Getwd()
+func Pwd() (string, error) { return NewShell(DefaultFS).Pwd() }
+
+// Pwd returns the current working directory.
+func (s *Shell) Pwd() (string, error) {
+	dir, err := s.FS.Getwd()
 	if err != nil {
 		return "", fmt.Errorf("%w: %v", ErrIO, err)
 	}
@@ -95,22 +108,26 @@ func Pwd() (string, error) {
 }
 
 // Cd changes the current working directory.
-func Cd(dir string) error {
-	err := os.Chdir(dir)
+func Cd(dir string) error { return NewShell(DefaultFS).Cd(dir) }
+
+// Cd changes the current working directory.
+func (s *Shell) Cd(dir string) error {
+	err := s.FS.Chdir(dir)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrIO, err)
 	}
 	return nil
 }
 
-// Exec executes a command and returns its output.
+// Exec executes a command and returns its combined stdout and stderr. It is
+// a thin compatibility wrapper around Run, which exposes stdout, stderr, and
+// the exit code separately.
 func Exec(ctx context.Context, command string, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, command, args...)
-	output, err := cmd.CombinedOutput()
+	result, err := Run(ctx, command, args...)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrCommandExecution, err)
+		return "", err
 	}
-	return string(output), nil
+	return string(result.Stdout) + string(result.Stderr), nil
 }
 
 // ReadLine reads a line from stdin.
@@ -125,7 +142,12 @@ func ReadLine() (string, error) {
 
 // WriteFile writes content to a file.
 func WriteFile(filename, content string) error {
-	err := os.WriteFile(filename, []byte(content), 0644)
+	return NewShell(DefaultFS).WriteFile(filename, content)
+}
+
+// WriteFile writes content to a file.
+func (s *Shell) WriteFile(filename, content string) error {
+	err := s.FS.WriteFile(filename, []byte(content), 0644)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrIO, err)
 	}
@@ -134,12 +156,16 @@ func WriteFile(filename, content string) error {
 
 // AppendFile appends content to a file.
 func AppendFile(filename, content string) error {
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
+	return NewShell(DefaultFS).AppendFile(filename, content)
+}
+
+// AppendFile appends content to a file.
+func (s *Shell) AppendFile(filename, content string) error {
+	existing, err := s.FS.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("%w: %v", ErrIO, err)
 	}
-	defer f.Close()
-	if _, err = f.WriteString(content); err != nil {
+	if err := s.FS.WriteFile(filename, append(existing, content...), 0644); err != nil {
 		return fmt.Errorf("%w: %v", ErrIO, err)
 	}
 	return nil
@@ -159,25 +185,24 @@ func SetEnv(key, value string) error {
 	return nil
 }
 
-// Cut extracts sections from each line of input.
+// Cut extracts sections from each line of input. It is a thin wrapper
+// around the streaming CutReader.
 func Cut(input, delimiter string, fields []int) []string {
-	var result []string
-	for _, line := range strings.Split(input, "\n") {
-		parts := strings.Split(line, delimiter)
-		var selected []string
-		for _, field := range fields {
-			if field > 0 && field <= len(parts) {
-				selected = append(selected, parts[field-1])
-			}
-		}
-		result = append(result, strings.Join(selected, delimiter))
+	var buf bytes.Buffer
+	if err := CutReader(delimiter, fields, strings.NewReader(input), &buf); err != nil {
+		return nil
 	}
-	return result
+	return strings.Split(buf.String(), "\n")
 }
 
-// Sed performs simple string substitutions.
+// Sed performs simple string substitutions, line by line. It is a thin
+// wrapper around the streaming SedReader.
 func Sed(input, old, new string) string {
-	return strings.ReplaceAll(input, old, new)
+	var buf bytes.Buffer
+	if err := SedReader(old, new, strings.NewReader(input), &buf); err != nil {
+		return input
+	}
+	return buf.String()
 }
 
 // Awk simulates basic awk functionality.
@@ -194,9 +219,12 @@ func Awk(input string, pattern string, action func([]string) string) []string {
 }
 
 // Find simulates the find command.
-func Find(root, pattern string) ([]string, error) {
+func Find(root, pattern string) ([]string, error) { return NewShell(DefaultFS).Find(root, pattern) }
+
+// Find simulates the find command.
+func (s *Shell) Find(root, pattern string) ([]string, error) {
 	var matches []string
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err := s.FS.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -218,17 +246,18 @@ func Xargs(ctx context.Context, input []string, command string, args ...string)
 	var output strings.Builder
 	for _, item := range input {
 		cmdArgs := append(args, item)
-		cmd := exec.CommandContext(ctx, command, cmdArgs...)
-		out, err := cmd.CombinedOutput()
+		result, err := Run(ctx, command, cmdArgs...)
 		if err != nil {
-			return "", fmt.Errorf("%w: %v", ErrCommandExecution, err)
+			return "", err
 		}
-		output.Write(out)
+		output.Write(result.Stdout)
 	}
 	return output.String(), nil
 }
 
-// Sort sorts lines of text.
+// Sort sorts lines of text. Unlike SortReader, it sorts the slice directly
+// rather than joining on "\n", so an element containing an embedded newline
+// is kept intact instead of being split into two lines.
 func Sort(input []string) []string {
 	sorted := make([]string, len(input))
 	copy(sorted, input)
@@ -236,7 +265,9 @@ func Sort(input []string) []string {
 	return sorted
 }
 
-// Uniq removes adjacent duplicate lines.
+// Uniq removes adjacent duplicate lines. Unlike UniqReader, it dedupes the
+// slice directly rather than joining on "\n", so an element containing an
+// embedded newline is kept intact instead of being split into two lines.
 func Uniq(input []string) []string {
 	var result []string
 	for i, line := range input {
@@ -247,11 +278,14 @@ func Uniq(input []string) []string {
 	return result
 }
 
-// Wc counts lines, words, and characters.
+// Wc counts lines, words, and characters. It is a thin wrapper around the
+// streaming WcReader.
 func Wc(input string) (lines, words, chars int) {
-	lines = strings.Count(input, "\n")
-	words = len(strings.Fields(input))
-	chars = len(input)
+	var buf bytes.Buffer
+	if err := WcReader(strings.NewReader(input), &buf); err != nil {
+		return 0, 0, 0
+	}
+	fmt.Sscanf(buf.String(), "%d %d %d", &lines, &words, &chars)
 	return
 }
 
@@ -282,44 +316,43 @@ func Tr(input, from, to string) (string, error) {
 	}, input), nil
 }
 
-// Head returns the first n lines of input.
+// Head returns the first n lines of input. It is a thin wrapper around the
+// streaming HeadReader.
 func Head(input string, n int) string {
-	lines := strings.SplitN(input, "\n", n+1)
-	return strings.Join(lines[:min(n, len(lines))], "\n")
+	var buf bytes.Buffer
+	if err := HeadReader(n, strings.NewReader(input), &buf); err != nil {
+		return input
+	}
+	return buf.String()
 }
 
-// Tail returns the last n lines of input.
+// Tail returns the last n lines of input. It is a thin wrapper around the
+// streaming TailReader, which keeps only a fixed-size ring buffer of the
+// last n lines in memory.
 func Tail(input string, n int) string {
-	lines := strings.Split(input, "\n")
-	start := max(0, len(lines)-n)
-	return strings.Join(lines[start:], "\n")
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
+	var buf bytes.Buffer
+	if err := TailReader(n, strings.NewReader(input), &buf); err != nil {
+		return input
 	}
-	return b
+	return buf.String()
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
+// Test simulates the test command for file operations and string comparisons.
+func Test(condition string, args ...string) (bool, error) {
+	return NewShell(DefaultFS).Test(condition, args...)
 }
 
 // Test simulates the test command for file operations and string comparisons.
-func Test(condition string, args ...string) (bool, error) {
+func (s *Shell) Test(condition string, args ...string) (bool, error) {
 	switch condition {
 	case "-e":
-		_, err := os.Stat(args[0])
+		_, err := s.FS.Stat(args[0])
 		return err == nil, nil
 	case "-f":
-		info, err := os.Stat(args[0])
+		info, err := s.FS.Stat(args[0])
 		return err == nil && !info.IsDir(), nil
 	case "-d":
-		info, err := os.Stat(args[0])
+		info, err := s.FS.Stat(args[0])
 		return err == nil && info.IsDir(), nil
 	case "-z":
 		return len(args[0]) == 0, nil
@@ -336,11 +369,11 @@ func Test(condition string, args ...string) (bool, error) {
 
 // Expr evaluates a simple arithmetic expression.
 func Expr(ctx context.Context, expression string) (int, error) {
-	output, err := Exec(ctx, "expr", strings.Fields(expression)...)
+	output, err := Run(ctx, "expr", strings.Fields(expression)...)
 	if err != nil {
-		return 0, fmt.Errorf("%w: %v", ErrCommandExecution, err)
+		return 0, err
 	}
-	result, err := strconv.Atoi(strings.TrimSpace(output))
+	result, err := strconv.Atoi(strings.TrimSpace(string(output.Stdout)))
 	if err != nil {
 		return 0, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
 	}
@@ -0,0 +1,35 @@
+package bashlike
+
+import "testing"
+
+// TestSortPreservesEmbeddedNewlines checks that Sort treats each slice
+// element atomically instead of splitting one containing "\n" into two
+// output lines (a regression from routing it through SortReader via
+// strings.Join/strings.Split).
+func TestSortPreservesEmbeddedNewlines(t *testing.T) {
+	got := Sort([]string{"b\na", "c"})
+	want := []string{"b\na", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
+
+// TestUniqPreservesEmbeddedNewlines is the Uniq analog of
+// TestSortPreservesEmbeddedNewlines.
+func TestUniqPreservesEmbeddedNewlines(t *testing.T) {
+	got := Uniq([]string{"a\nb", "a\nb", "c"})
+	want := []string{"a\nb", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
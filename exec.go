@@ -0,0 +1,104 @@
+package bashlike
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// Result is the structured outcome of running a command: separate
+// stdout/stderr streams, the process exit code, how long it ran, and the
+// signal that killed it, if any.
+type Result struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	Duration time.Duration
+	Signal   os.Signal
+}
+
+// ExitError is returned by Run when a command exits with a non-zero status.
+// It carries the Result so callers can still inspect stdout/stderr.
+type ExitError struct {
+	*Result
+	Err error
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%v: exit code %d: %v", ErrCommandExecution, e.ExitCode, e.Err)
+}
+
+func (e *ExitError) Unwrap() error { return ErrCommandExecution }
+
+// RunOpts configures Run: stdin/stdout/stderr streaming, environment,
+// working directory, and a timeout.
+type RunOpts struct {
+	Stdin   io.Reader
+	Env     []string
+	Dir     string
+	Stdout  io.Writer
+	Stderr  io.Writer
+	Timeout time.Duration
+}
+
+// Run executes command with the default options and returns a structured
+// Result. Use RunOpts.Run for streaming, timeouts, or a custom environment.
+func Run(ctx context.Context, command string, args ...string) (*Result, error) {
+	return RunOpts{}.Run(ctx, command, args...)
+}
+
+// Run executes command according to o, capturing stdout/stderr into the
+// returned Result (and, if set, tee-ing them to o.Stdout/o.Stderr as they
+// arrive). A non-zero exit returns a populated Result alongside *ExitError.
+func (o RunOpts) Run(ctx context.Context, command string, args ...string) (*Result, error) {
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = o.Dir
+	cmd.Env = o.Env
+	cmd.Stdin = o.Stdin
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	if o.Stdout != nil {
+		cmd.Stdout = io.MultiWriter(o.Stdout, &stdoutBuf)
+	}
+	cmd.Stderr = &stderrBuf
+	if o.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(o.Stderr, &stderrBuf)
+	}
+
+	start := time.Now()
+	runErr := cmd.Run()
+
+	result := &Result{
+		Stdout:   stdoutBuf.Bytes(),
+		Stderr:   stderrBuf.Bytes(),
+		Duration: time.Since(start),
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+		if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			result.Signal = ws.Signal()
+		}
+	}
+
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return result, &ExitError{Result: result, Err: runErr}
+		}
+		return result, fmt.Errorf("%w: %v", ErrCommandExecution, runErr)
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package bashlike
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRunExitCodeAndStderr checks that a non-zero exit populates Result
+// (ExitCode, Stderr) and returns a typed *ExitError rather than swallowing
+// the streams.
+func TestRunExitCodeAndStderr(t *testing.T) {
+	result, err := Run(context.Background(), "sh", "-c", "echo boom 1>&2; exit 3")
+	if err == nil {
+		t.Fatal("expected an error for a non-zero exit")
+	}
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("err = %v (%T), want *ExitError", err, err)
+	}
+	if result.ExitCode != 3 {
+		t.Fatalf("ExitCode = %d, want 3", result.ExitCode)
+	}
+	if got := strings.TrimSpace(string(result.Stderr)); got != "boom" {
+		t.Fatalf("Stderr = %q, want %q", got, "boom")
+	}
+	if exitErr.Result != result {
+		t.Fatalf("ExitError.Result = %p, want the same Result returned alongside it (%p)", exitErr.Result, result)
+	}
+}
+
+// TestRunOptsTimeoutCancelsProcess checks that RunOpts.Timeout stops a
+// long-running command instead of waiting for it to finish.
+func TestRunOptsTimeoutCancelsProcess(t *testing.T) {
+	start := time.Now()
+	_, err := RunOpts{Timeout: 50 * time.Millisecond}.Run(context.Background(), "sleep", "5")
+	elapsed := time.Since(start)
+	if err == nil {
+		t.Fatal("expected an error from a timed-out command")
+	}
+	if elapsed > 4*time.Second {
+		t.Fatalf("Run took %v, want it cut short well before the 5s sleep finished", elapsed)
+	}
+}
+
+// TestRunSuccess checks the happy path: stdout captured, zero exit code, no
+// error.
+func TestRunSuccess(t *testing.T) {
+	result, err := Run(context.Background(), "echo", "hello")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := strings.TrimSpace(string(result.Stdout)); got != "hello" {
+		t.Fatalf("Stdout = %q, want %q", got, "hello")
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("ExitCode = %d, want 0", result.ExitCode)
+	}
+}
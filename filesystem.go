@@ -0,0 +1,469 @@
+package bashlike
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileSystem abstracts the filesystem operations used throughout this
+// package so that callers can swap in an in-memory or sandboxed
+// implementation instead of touching the real disk.
+type FileSystem interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	Open(name string) (io.ReadCloser, error)
+	ReadDir(name string) ([]os.FileInfo, error)
+	MkdirAll(path string, perm os.FileMode) error
+	RemoveAll(path string) error
+	Stat(name string) (os.FileInfo, error)
+	Walk(root string, fn filepath.WalkFunc) error
+	Getwd() (string, error)
+	Chdir(dir string) error
+}
+
+// DefaultFS is the FileSystem used by the package-level functions (Cat, Ls,
+// Mkdir, etc). Replace it to redirect every call in this package, or use
+// Shell for a scoped instance instead.
+var DefaultFS FileSystem = OSFS{}
+
+// Shell bundles a FileSystem so callers can run the package's operations
+// against a specific backend (e.g. a MemFS in tests, or a BasePathFS when
+// running untrusted scripts) without mutating DefaultFS.
+type Shell struct {
+	FS FileSystem
+}
+
+// NewShell returns a Shell backed by fs. A nil fs falls back to OSFS{}.
+func NewShell(fs FileSystem) *Shell {
+	if fs == nil {
+		fs = OSFS{}
+	}
+	return &Shell{FS: fs}
+}
+
+// fsContextKey is the context.Value key WithFS/FSFromContext use to carry a
+// FileSystem alongside a context.Context.
+type fsContextKey struct{}
+
+// WithFS returns a copy of ctx that carries fs. Shell.Parse/Shell.RunScript
+// attach the Shell's FS this way so builtins dispatched through ctx (see
+// FSFromContext) run against it instead of the package-level DefaultFS.
+func WithFS(ctx context.Context, fs FileSystem) context.Context {
+	return context.WithValue(ctx, fsContextKey{}, fs)
+}
+
+// FSFromContext returns the FileSystem attached to ctx via WithFS, or
+// DefaultFS if none was attached.
+func FSFromContext(ctx context.Context) FileSystem {
+	if fs, ok := ctx.Value(fsContextKey{}).(FileSystem); ok {
+		return fs
+	}
+	return DefaultFS
+}
+
+// OSFS implements FileSystem on top of the real operating system, matching
+// the behavior this package had before FileSystem was introduced.
+type OSFS struct{}
+
+func (OSFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+func (OSFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+func (OSFS) Open(name string) (io.ReadCloser, error) { return os.Open(name) }
+func (OSFS) ReadDir(name string) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+func (OSFS) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+func (OSFS) RemoveAll(path string) error                  { return os.RemoveAll(path) }
+func (OSFS) Stat(name string) (os.FileInfo, error)        { return os.Stat(name) }
+func (OSFS) Walk(root string, fn filepath.WalkFunc) error { return filepath.Walk(root, fn) }
+func (OSFS) Getwd() (string, error)                       { return os.Getwd() }
+func (OSFS) Chdir(dir string) error                       { return os.Chdir(dir) }
+
+// memNode is a single file or directory inside a MemFS.
+type memNode struct {
+	isDir   bool
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// memFileInfo adapts a memNode to os.FileInfo.
+type memFileInfo struct {
+	name string
+	node memNode
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return int64(len(fi.node.data)) }
+func (fi memFileInfo) Mode() os.FileMode  { return fi.node.mode }
+func (fi memFileInfo) ModTime() time.Time { return fi.node.modTime }
+func (fi memFileInfo) IsDir() bool        { return fi.node.isDir }
+func (fi memFileInfo) Sys() interface{}   { return nil }
+
+// MemFS is an in-memory FileSystem keyed by cleaned, slash-separated paths.
+// It is safe for concurrent use.
+type MemFS struct {
+	mu    sync.RWMutex
+	nodes map[string]memNode
+	cwd   string
+}
+
+// NewMemFS returns an empty MemFS rooted at "/".
+func NewMemFS() *MemFS {
+	return &MemFS{
+		nodes: map[string]memNode{"/": {isDir: true, mode: os.ModeDir | 0755, modTime: time.Time{}}},
+		cwd:   "/",
+	}
+}
+
+func (m *MemFS) resolve(name string) string {
+	if !filepath.IsAbs(name) {
+		m.mu.RLock()
+		cwd := m.cwd
+		m.mu.RUnlock()
+		name = filepath.Join(cwd, name)
+	}
+	return filepath.ToSlash(filepath.Clean(name))
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	path := m.resolve(name)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	node, ok := m.nodes[path]
+	if !ok || node.isDir {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	out := make([]byte, len(node.data))
+	copy(out, node.data)
+	return out, nil
+}
+
+func (m *MemFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	path := m.resolve(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.mkdirAllLocked(filepath.ToSlash(filepath.Dir(path)), 0755); err != nil {
+		return err
+	}
+	buf := make([]byte, len(data))
+	copy(buf, data)
+	m.nodes[path] = memNode{data: buf, mode: perm, modTime: time.Time{}}
+	return nil
+}
+
+func (m *MemFS) Open(name string) (io.ReadCloser, error) {
+	data, err := m.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]os.FileInfo, error) {
+	path := m.resolve(name)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	node, ok := m.nodes[path]
+	if !ok || !node.isDir {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	prefix := path
+	if prefix != "/" {
+		prefix += "/"
+	}
+	var infos []os.FileInfo
+	for p, n := range m.nodes {
+		if p == path || !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(p, prefix)
+		if strings.Contains(rest, "/") {
+			continue
+		}
+		infos = append(infos, memFileInfo{name: rest, node: n})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	return infos, nil
+}
+
+func (m *MemFS) mkdirAllLocked(path string, perm os.FileMode) error {
+	path = filepath.ToSlash(filepath.Clean(path))
+	if path == "/" || path == "." {
+		return nil
+	}
+	if n, ok := m.nodes[path]; ok {
+		if !n.isDir {
+			return &os.PathError{Op: "mkdir", Path: path, Err: os.ErrExist}
+		}
+		return nil
+	}
+	if err := m.mkdirAllLocked(filepath.ToSlash(filepath.Dir(path)), perm); err != nil {
+		return err
+	}
+	m.nodes[path] = memNode{isDir: true, mode: os.ModeDir | perm}
+	return nil
+}
+
+func (m *MemFS) MkdirAll(path string, perm os.FileMode) error {
+	path = m.resolve(path)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAllLocked(path, perm)
+}
+
+func (m *MemFS) RemoveAll(path string) error {
+	target := m.resolve(path)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := target + "/"
+	for p := range m.nodes {
+		if p == target || strings.HasPrefix(p, prefix) {
+			delete(m.nodes, p)
+		}
+	}
+	return nil
+}
+
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	path := m.resolve(name)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	node, ok := m.nodes[path]
+	if !ok {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	return memFileInfo{name: filepath.Base(path), node: node}, nil
+}
+
+// Walk mirrors filepath.Walk: lexical order, SkipDir support, and error
+// propagation from both the walk itself and fn.
+func (m *MemFS) Walk(root string, fn filepath.WalkFunc) error {
+	path := m.resolve(root)
+	under := func(p, dir string) bool {
+		return dir == "/" || p == dir || strings.HasPrefix(p, dir+"/")
+	}
+	m.mu.RLock()
+	var paths []string
+	for p := range m.nodes {
+		if under(p, path) {
+			paths = append(paths, p)
+		}
+	}
+	// Compare with "/" ranked below every other byte, so each directory's
+	// entries follow it directly, in name order, as in filepath.Walk.
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.ReplaceAll(paths[i], "/", "\x00") < strings.ReplaceAll(paths[j], "/", "\x00")
+	})
+	nodes := make(map[string]memNode, len(paths))
+	for _, p := range paths {
+		nodes[p] = m.nodes[p]
+	}
+	m.mu.RUnlock()
+
+	if len(paths) == 0 {
+		return fn(root, nil, &os.PathError{Op: "lstat", Path: root, Err: os.ErrNotExist})
+	}
+
+	var skip string
+	for _, p := range paths {
+		if skip != "" && under(p, skip) {
+			continue
+		}
+		node := nodes[p]
+		err := fn(p, memFileInfo{name: filepath.Base(p), node: node}, nil)
+		if err == filepath.SkipDir {
+			if node.isDir {
+				skip = p
+			} else {
+				// filepath.Walk also honors SkipDir returned for a
+				// non-directory file: the remaining entries in its
+				// containing directory are skipped.
+				skip = filepath.ToSlash(filepath.Dir(p))
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemFS) Getwd() (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cwd, nil
+}
+
+func (m *MemFS) Chdir(dir string) error {
+	path := m.resolve(dir)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node, ok := m.nodes[path]
+	if !ok || !node.isDir {
+		return &os.PathError{Op: "chdir", Path: dir, Err: os.ErrNotExist}
+	}
+	m.cwd = path
+	return nil
+}
+
+// BasePathFS confines all operations inside Root, rejecting any path that
+// would escape it via "..". Returned paths are translated back to
+// root-relative form. It is safe for concurrent use.
+type BasePathFS struct {
+	FS   FileSystem
+	Root string
+
+	mu  sync.RWMutex
+	cwd string // root-relative, e.g. "/" or "/sub"
+}
+
+// NewBasePathFS returns a BasePathFS rooted at root, backed by fs. A nil fs
+// defaults to OSFS{}.
+func NewBasePathFS(fs FileSystem, root string) *BasePathFS {
+	if fs == nil {
+		fs = OSFS{}
+	}
+	return &BasePathFS{FS: fs, Root: filepath.Clean(root), cwd: "/"}
+}
+
+func (b *BasePathFS) realPath(name string) (string, error) {
+	full := name
+	if !filepath.IsAbs(name) {
+		b.mu.RLock()
+		cwd := b.cwd
+		b.mu.RUnlock()
+		full = cwd + string(filepath.Separator) + name
+	}
+	cleaned := filepath.Clean(strings.TrimLeft(full, string(filepath.Separator)))
+	if cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: path %q escapes base path %q", ErrInvalidArgument, name, b.Root)
+	}
+	if cleaned == "." {
+		return b.Root, nil
+	}
+	return filepath.Join(b.Root, cleaned), nil
+}
+
+func (b *BasePathFS) rel(real string) string {
+	rel, err := filepath.Rel(b.Root, real)
+	if err != nil {
+		return real
+	}
+	if rel == "." {
+		return "/"
+	}
+	return "/" + filepath.ToSlash(rel)
+}
+
+func (b *BasePathFS) ReadFile(name string) ([]byte, error) {
+	real, err := b.realPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.FS.ReadFile(real)
+}
+
+func (b *BasePathFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	real, err := b.realPath(name)
+	if err != nil {
+		return err
+	}
+	return b.FS.WriteFile(real, data, perm)
+}
+
+func (b *BasePathFS) Open(name string) (io.ReadCloser, error) {
+	real, err := b.realPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.FS.Open(real)
+}
+
+func (b *BasePathFS) ReadDir(name string) ([]os.FileInfo, error) {
+	real, err := b.realPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.FS.ReadDir(real)
+}
+
+func (b *BasePathFS) MkdirAll(path string, perm os.FileMode) error {
+	real, err := b.realPath(path)
+	if err != nil {
+		return err
+	}
+	return b.FS.MkdirAll(real, perm)
+}
+
+func (b *BasePathFS) RemoveAll(path string) error {
+	real, err := b.realPath(path)
+	if err != nil {
+		return err
+	}
+	return b.FS.RemoveAll(real)
+}
+
+func (b *BasePathFS) Stat(name string) (os.FileInfo, error) {
+	real, err := b.realPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.FS.Stat(real)
+}
+
+func (b *BasePathFS) Walk(root string, fn filepath.WalkFunc) error {
+	real, err := b.realPath(root)
+	if err != nil {
+		return err
+	}
+	return b.FS.Walk(real, func(path string, info os.FileInfo, walkErr error) error {
+		return fn(b.rel(path), info, walkErr)
+	})
+}
+
+func (b *BasePathFS) Getwd() (string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.cwd, nil
+}
+
+func (b *BasePathFS) Chdir(dir string) error {
+	real, err := b.realPath(dir)
+	if err != nil {
+		return err
+	}
+	info, err := b.FS.Stat(real)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: %s is not a directory", ErrInvalidArgument, dir)
+	}
+	b.mu.Lock()
+	b.cwd = b.rel(real)
+	b.mu.Unlock()
+	return nil
+}
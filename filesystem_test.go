@@ -0,0 +1,71 @@
+package bashlike
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMemFSWalkSkipDirOnFile checks that returning filepath.SkipDir from a
+// non-directory entry skips the rest of its containing directory, matching
+// filepath.Walk's documented behavior.
+func TestMemFSWalkSkipDirOnFile(t *testing.T) {
+	mem := NewMemFS()
+	mem.MkdirAll("/dir", 0755)
+	mem.WriteFile("/dir/a.txt", []byte("a"), 0644)
+	mem.WriteFile("/dir/b.txt", []byte("b"), 0644)
+	mem.WriteFile("/dir/c.txt", []byte("c"), 0644)
+
+	var got []string
+	err := mem.Walk("/dir", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			got = append(got, filepath.Base(path))
+			if filepath.Base(path) == "b.txt" {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	want := []string{"a.txt", "b.txt"}
+	if len(got) != len(want) {
+		t.Fatalf("visited %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("visited %v, want %v", got, want)
+		}
+	}
+}
+
+// TestBasePathFSChdirPwd checks that Chdir actually moves the cwd: Getwd
+// reflects it afterwards, and a subsequent relative path resolves against
+// it rather than always against Root.
+func TestBasePathFSChdirPwd(t *testing.T) {
+	mem := NewMemFS()
+	mem.MkdirAll("/root/sub", 0755)
+	mem.WriteFile("/root/sub/file.txt", []byte("hi"), 0644)
+
+	bp := NewBasePathFS(mem, "/root")
+	if wd, err := bp.Getwd(); err != nil || wd != "/" {
+		t.Fatalf("initial Getwd = %q, %v, want /", wd, err)
+	}
+	if err := bp.Chdir("/sub"); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	if wd, err := bp.Getwd(); err != nil || wd != "/sub" {
+		t.Fatalf("Getwd after Chdir = %q, %v, want /sub", wd, err)
+	}
+	data, err := bp.ReadFile("file.txt")
+	if err != nil {
+		t.Fatalf("ReadFile relative to new cwd: %v", err)
+	}
+	if string(data) != "hi" {
+		t.Fatalf("got %q, want %q", data, "hi")
+	}
+}
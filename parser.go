@@ -0,0 +1,523 @@
+package bashlike
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BuiltinFunc is a shell builtin: it reads stdin and writes its result to
+// stdout, in the style of the streaming Pipe.Cmd closures Parse builds
+// commands out of.
+type BuiltinFunc func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error
+
+// builtins holds the registered builtin commands, keyed by name.
+var builtins = NewConcurrentMap()
+
+// RegisterBuiltin registers fn under name so Parse and RunScript dispatch to
+// it instead of falling through to exec.CommandContext.
+func RegisterBuiltin(name string, fn BuiltinFunc) {
+	builtins.Set(name, fn)
+}
+
+func init() {
+	RegisterBuiltin("cat", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		if len(args) == 0 {
+			_, err := io.Copy(stdout, stdin)
+			return err
+		}
+		content, err := NewShell(FSFromContext(ctx)).Cat(args[0])
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdout, content)
+		return err
+	})
+	RegisterBuiltin("ls", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		names, err := NewShell(FSFromContext(ctx)).Ls(dir)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdout, strings.Join(names, "\n"))
+		return err
+	})
+	RegisterBuiltin("find", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		if len(args) < 2 {
+			return fmt.Errorf("%w: find requires a root and a pattern", ErrInvalidArgument)
+		}
+		matches, err := NewShell(FSFromContext(ctx)).Find(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdout, strings.Join(matches, "\n"))
+		return err
+	})
+	RegisterBuiltin("tr", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		if len(args) < 2 {
+			return fmt.Errorf("%w: tr requires a 'from' and 'to' set", ErrInvalidArgument)
+		}
+		text, err := readAll(stdin)
+		if err != nil {
+			return err
+		}
+		out, err := Tr(text, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdout, out)
+		return err
+	})
+	RegisterBuiltin("awk", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		if len(args) < 1 {
+			return fmt.Errorf("%w: awk requires a pattern", ErrInvalidArgument)
+		}
+		text, err := readAll(stdin)
+		if err != nil {
+			return err
+		}
+		result := Awk(text, args[0], func(fields []string) string { return strings.Join(fields, " ") })
+		_, err = io.WriteString(stdout, strings.Join(result, "\n"))
+		return err
+	})
+}
+
+func readAll(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	return string(data), nil
+}
+
+func lineCountArg(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+	return n, nil
+}
+
+func parseCutArgs(args []string) (delimiter string, fields []int, err error) {
+	delimiter = "\t"
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-d":
+			i++
+			if i >= len(args) {
+				return "", nil, fmt.Errorf("%w: -d requires a delimiter", ErrInvalidArgument)
+			}
+			delimiter = args[i]
+		case "-f":
+			i++
+			if i >= len(args) {
+				return "", nil, fmt.Errorf("%w: -f requires a field list", ErrInvalidArgument)
+			}
+			for _, raw := range strings.Split(args[i], ",") {
+				n, convErr := strconv.Atoi(raw)
+				if convErr != nil {
+					return "", nil, fmt.Errorf("%w: %v", ErrInvalidArgument, convErr)
+				}
+				fields = append(fields, n)
+			}
+		}
+	}
+	return delimiter, fields, nil
+}
+
+func parseSedExpr(expr string) (old, new string, err error) {
+	if !strings.HasPrefix(expr, "s/") {
+		return "", "", fmt.Errorf("%w: sed expression must be of the form s/old/new/", ErrInvalidArgument)
+	}
+	parts := strings.Split(expr, "/")
+	if len(parts) < 3 {
+		return "", "", fmt.Errorf("%w: sed expression must be of the form s/old/new/", ErrInvalidArgument)
+	}
+	return parts[1], parts[2], nil
+}
+
+// command is a single simple command in a pipeline: a builtin or external
+// program name, its arguments, and any redirections attached to it.
+type command struct {
+	words      []string
+	stdinFile  string
+	stdoutFile string
+	appendOut  bool
+}
+
+// Parse tokenizes a single bash-like pipeline (commands joined by `|`, with
+// optional `<`/`>`/`>>` redirection) and builds a runnable Pipe graph against
+// DefaultFS. Builtins registered via RegisterBuiltin are dispatched
+// directly; everything else falls through to exec.CommandContext. Use
+// Shell.Parse to run against a specific FileSystem (e.g. a MemFS or
+// BasePathFS) instead of DefaultFS.
+func Parse(script string) (*Pipe, error) {
+	return NewShell(DefaultFS).Parse(script)
+}
+
+// Parse is like the package-level Parse, but builds a Pipe that reads,
+// writes, and redirects through s.FS instead of DefaultFS.
+func (s *Shell) Parse(script string) (*Pipe, error) {
+	tokens, err := tokenizeShell(script)
+	if err != nil {
+		return nil, err
+	}
+	cmds, err := splitPipeline(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmds) == 0 {
+		return nil, fmt.Errorf("%w: empty command", ErrInvalidArgument)
+	}
+	return buildPipe(cmds, s.FS)
+}
+
+// RunScript parses and executes script against stdin, honoring `;`, `&&`,
+// and `||` sequencing between pipelines, and returns the reader produced by
+// the last pipeline that ran. (Run is already taken by the process-exec
+// API in exec.go.) It runs against DefaultFS; use Shell.RunScript to run
+// against a specific FileSystem instead.
+func RunScript(ctx context.Context, script string, stdin io.Reader) (io.Reader, error) {
+	return NewShell(DefaultFS).RunScript(ctx, script, stdin)
+}
+
+// RunScript is like the package-level RunScript, but runs against s.FS
+// instead of DefaultFS.
+func (s *Shell) RunScript(ctx context.Context, script string, stdin io.Reader) (io.Reader, error) {
+	statements, err := splitStatements(script)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		out     io.Reader = stdin
+		lastErr error
+	)
+	for _, st := range statements {
+		switch st.op {
+		case "&&":
+			if lastErr != nil {
+				continue
+			}
+		case "||":
+			if lastErr == nil {
+				continue
+			}
+		}
+		pipe, err := s.Parse(st.script)
+		if err != nil {
+			return nil, err
+		}
+		out, lastErr = pipe.Execute(ctx, out)
+	}
+	return out, lastErr
+}
+
+type statement struct {
+	script string
+	op     string // operator that PRECEDES this statement: "", ";", "&&", "||"
+}
+
+func splitStatements(script string) ([]statement, error) {
+	tokens, err := tokenizeShell(script)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		statements []statement
+		current    []shellToken
+		op         string
+	)
+	flush := func(next string) {
+		statements = append(statements, statement{script: joinTokens(current), op: op})
+		current = nil
+		op = next
+	}
+	for _, tok := range tokens {
+		if tok.op && (tok.text == ";" || tok.text == "&&" || tok.text == "||") {
+			flush(tok.text)
+			continue
+		}
+		current = append(current, tok)
+	}
+	if len(current) > 0 || len(statements) == 0 {
+		statements = append(statements, statement{script: joinTokens(current), op: op})
+	}
+	return statements, nil
+}
+
+// joinTokens re-quotes tokens so they can be safely re-tokenized by Parse
+// without re-triggering $VAR expansion (already performed once). Operator
+// tokens are written bare; everything else (including a word that happens to
+// look like an operator because it came from a quoted string) is single-quoted
+// so splitPipeline can't mistake it for one.
+func joinTokens(tokens []shellToken) string {
+	var b strings.Builder
+	for i, tok := range tokens {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if tok.op {
+			b.WriteString(tok.text)
+			continue
+		}
+		b.WriteByte('\'')
+		b.WriteString(strings.ReplaceAll(tok.text, "'", `'\''`))
+		b.WriteByte('\'')
+	}
+	return b.String()
+}
+
+func splitPipeline(tokens []shellToken) ([]command, error) {
+	var (
+		cmds []command
+		cur  command
+	)
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if !tok.op {
+			cur.words = append(cur.words, tok.text)
+			continue
+		}
+		switch tok.text {
+		case "|":
+			cmds = append(cmds, cur)
+			cur = command{}
+		case "<":
+			i++
+			if i >= len(tokens) {
+				return nil, fmt.Errorf("%w: '<' requires a filename", ErrInvalidArgument)
+			}
+			cur.stdinFile = tokens[i].text
+		case ">":
+			i++
+			if i >= len(tokens) {
+				return nil, fmt.Errorf("%w: '>' requires a filename", ErrInvalidArgument)
+			}
+			cur.stdoutFile = tokens[i].text
+			cur.appendOut = false
+		case ">>":
+			i++
+			if i >= len(tokens) {
+				return nil, fmt.Errorf("%w: '>>' requires a filename", ErrInvalidArgument)
+			}
+			cur.stdoutFile = tokens[i].text
+			cur.appendOut = true
+		default:
+			cur.words = append(cur.words, tok.text)
+		}
+	}
+	if len(cur.words) > 0 || cur.stdinFile != "" || cur.stdoutFile != "" {
+		cmds = append(cmds, cur)
+	}
+	return cmds, nil
+}
+
+func buildPipe(cmds []command, fs FileSystem) (*Pipe, error) {
+	root := &Pipe{}
+	head := root
+	for i, cmd := range cmds {
+		if len(cmd.words) == 0 {
+			return nil, fmt.Errorf("%w: empty command", ErrInvalidArgument)
+		}
+		fn, err := cmdToPipeFn(cmd, fs)
+		if err != nil {
+			return nil, err
+		}
+		head.Cmd = fn
+		if i < len(cmds)-1 {
+			head.Next = &Pipe{}
+			head = head.Next
+		}
+	}
+	return root, nil
+}
+
+// cmdToPipeFn builds the Pipe.Cmd closure for cmd. It resolves redirection
+// and dispatches to fs directly, and also attaches fs to ctx (via WithFS) so
+// any builtin it dispatches to (see FSFromContext) runs against the same
+// FileSystem instead of the package-level DefaultFS.
+func cmdToPipeFn(cmd command, fs FileSystem) (func(context.Context, io.Reader) (io.Reader, error), error) {
+	name, args := cmd.words[0], cmd.words[1:]
+	sh := NewShell(fs)
+	return func(ctx context.Context, r io.Reader) (io.Reader, error) {
+		ctx = WithFS(ctx, fs)
+		if cmd.stdinFile != "" {
+			data, err := fs.ReadFile(cmd.stdinFile)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrIO, err)
+			}
+			r = bytes.NewReader(data)
+		}
+		var buf bytes.Buffer
+		if raw, ok := builtins.Get(name); ok {
+			fn := raw.(BuiltinFunc)
+			if err := fn(ctx, args, r, &buf); err != nil {
+				return nil, err
+			}
+		} else {
+			result, err := RunOpts{Stdin: r}.Run(ctx, name, args...)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(result.Stdout)
+		}
+		if cmd.stdoutFile != "" {
+			if cmd.appendOut {
+				if err := sh.AppendFile(cmd.stdoutFile, buf.String()); err != nil {
+					return nil, err
+				}
+			} else if err := sh.WriteFile(cmd.stdoutFile, buf.String()); err != nil {
+				return nil, err
+			}
+			return &bytes.Buffer{}, nil
+		}
+		return &buf, nil
+	}, nil
+}
+
+// shellToken is one lexical unit produced by tokenizeShell. op is true only
+// for an operator character ("|", "<", ">", ">>", ";", "&&", "||") seen bare,
+// outside any quoting; the same character appearing inside quotes (or
+// escaped) becomes an ordinary word token with op false, so splitPipeline and
+// splitStatements can't mistake literal text for an operator.
+type shellToken struct {
+	text string
+	op   bool
+}
+
+// tokenizeShell splits script into words and operators, honoring single and
+// double quotes, backslash escapes, and $VAR/${VAR} expansion (via Env)
+// outside single quotes.
+func tokenizeShell(script string) ([]shellToken, error) {
+	var (
+		tokens  []shellToken
+		word    strings.Builder
+		hasWord bool
+		runes   = []rune(script)
+		i       = 0
+	)
+	flush := func() {
+		if hasWord {
+			tokens = append(tokens, shellToken{text: word.String()})
+			word.Reset()
+			hasWord = false
+		}
+	}
+	for i < len(runes) {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			flush()
+			i++
+		case c == '\'':
+			hasWord = true
+			i++
+			for i < len(runes) && runes[i] != '\'' {
+				word.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated single quote", ErrInvalidArgument)
+			}
+			i++
+		case c == '"':
+			hasWord = true
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\' || runes[i+1] == '$') {
+					word.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '$' {
+					name, next := readVarName(runes, i+1)
+					word.WriteString(Env(name))
+					i = next
+					continue
+				}
+				word.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated double quote", ErrInvalidArgument)
+			}
+			i++
+		case c == '\\':
+			hasWord = true
+			if i+1 < len(runes) {
+				word.WriteRune(runes[i+1])
+				i += 2
+			} else {
+				i++
+			}
+		case c == '$':
+			hasWord = true
+			name, next := readVarName(runes, i+1)
+			word.WriteString(Env(name))
+			i = next
+		case c == '|' || c == '&':
+			flush()
+			if i+1 < len(runes) && runes[i+1] == c {
+				tokens = append(tokens, shellToken{text: string([]rune{c, c}), op: true})
+				i += 2
+			} else if c == '|' {
+				tokens = append(tokens, shellToken{text: "|", op: true})
+				i++
+			} else {
+				return nil, fmt.Errorf("%w: unsupported operator '&'", ErrInvalidArgument)
+			}
+		case c == '>':
+			flush()
+			if i+1 < len(runes) && runes[i+1] == '>' {
+				tokens = append(tokens, shellToken{text: ">>", op: true})
+				i += 2
+			} else {
+				tokens = append(tokens, shellToken{text: ">", op: true})
+				i++
+			}
+		case c == '<':
+			flush()
+			tokens = append(tokens, shellToken{text: "<", op: true})
+			i++
+		case c == ';':
+			flush()
+			tokens = append(tokens, shellToken{text: ";", op: true})
+			i++
+		default:
+			hasWord = true
+			word.WriteRune(c)
+			i++
+		}
+	}
+	flush()
+	return tokens, nil
+}
+
+func readVarName(runes []rune, i int) (string, int) {
+	braced := i < len(runes) && runes[i] == '{'
+	if braced {
+		i++
+	}
+	start := i
+	for i < len(runes) && (isAlnum(runes[i]) || runes[i] == '_') {
+		i++
+	}
+	name := string(runes[start:i])
+	if braced && i < len(runes) && runes[i] == '}' {
+		i++
+	}
+	return name, i
+}
+
+func isAlnum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,113 @@
+package bashlike
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestParseQuotedOperator checks that a quoted operator character is kept as
+// literal argument text instead of being treated as a redirect/pipe.
+func TestParseQuotedOperator(t *testing.T) {
+	cases := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"quoted-redirect", `echo ">"`, ">"},
+		{"quoted-pipe", `echo "|"`, "|"},
+		{"quoted-append", `echo ">>"`, ">>"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := Parse(tc.script)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tc.script, err)
+			}
+			r, err := p.Execute(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Execute(%q): %v", tc.script, err)
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll: %v", err)
+			}
+			if got := strings.TrimRight(string(data), "\n"); got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitPipelineQuotedOperatorIsWord(t *testing.T) {
+	tokens, err := tokenizeShell(`echo ">" file.txt`)
+	if err != nil {
+		t.Fatalf("tokenizeShell: %v", err)
+	}
+	cmds, err := splitPipeline(tokens)
+	if err != nil {
+		t.Fatalf("splitPipeline: %v", err)
+	}
+	if len(cmds) != 1 {
+		t.Fatalf("got %d commands, want 1", len(cmds))
+	}
+	cmd := cmds[0]
+	if cmd.stdoutFile != "" {
+		t.Fatalf("stdoutFile = %q, want empty (quoted '>' must not be a redirect)", cmd.stdoutFile)
+	}
+	want := []string{"echo", ">", "file.txt"}
+	if len(cmd.words) != len(want) {
+		t.Fatalf("words = %v, want %v", cmd.words, want)
+	}
+	for i := range want {
+		if cmd.words[i] != want[i] {
+			t.Fatalf("words = %v, want %v", cmd.words, want)
+		}
+	}
+}
+
+// TestShellParseUsesShellFS checks that Shell.Parse/Shell.RunScript run
+// builtins and redirection against the Shell's own FS rather than the
+// package-level DefaultFS, so a script can be sandboxed without mutating
+// global state.
+func TestShellParseUsesShellFS(t *testing.T) {
+	mem := NewMemFS()
+	if err := mem.WriteFile("/in.txt", []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sh := NewShell(mem)
+
+	p, err := sh.Parse("cat /in.txt")
+	if err != nil {
+		t.Fatalf("Shell.Parse: %v", err)
+	}
+	r, err := p.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("got %q, want %q", data, "hello")
+	}
+
+	if _, err := DefaultFS.ReadFile("/in.txt"); err == nil {
+		t.Fatal("DefaultFS.ReadFile(/in.txt) succeeded, want the file to only exist in the Shell's MemFS")
+	}
+
+	out, err := sh.RunScript(context.Background(), "cat /in.txt > /out.txt", nil)
+	if err != nil {
+		t.Fatalf("Shell.RunScript: %v", err)
+	}
+	io.ReadAll(out)
+	written, err := mem.ReadFile("/out.txt")
+	if err != nil {
+		t.Fatalf("redirected output did not land in the Shell's FS: %v", err)
+	}
+	if string(written) != "hello" {
+		t.Fatalf("got %q, want %q", written, "hello")
+	}
+}
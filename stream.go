@@ -0,0 +1,406 @@
+package bashlike
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// ScannerBufferSize is the maximum line length the Reader-based utilities
+// in this file will accept. Raise it before calling them if the input
+// contains unusually long lines.
+var ScannerBufferSize = 1024 * 1024
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), ScannerBufferSize)
+	return sc
+}
+
+// writeLines joins lines with newlines and writes them to w, matching how
+// the string-based utilities in bashlike.go join their results.
+func writeLines(w io.Writer, lines []string) error {
+	if _, err := io.WriteString(w, strings.Join(lines, "\n")); err != nil {
+		return fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	return nil
+}
+
+// GrepReader streams lines from r to w, writing only the lines matching
+// pattern. It scans line-by-line so memory use stays bounded regardless of
+// input size.
+func GrepReader(pattern string, r io.Reader, w io.Writer) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRegex, err)
+	}
+	sc := newLineScanner(r)
+	first := true
+	for sc.Scan() {
+		line := sc.Text()
+		if !re.MatchString(line) {
+			continue
+		}
+		if !first {
+			io.WriteString(w, "\n")
+		}
+		first = false
+		io.WriteString(w, line)
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	return nil
+}
+
+// CutReader streams lines from r to w, writing the requested delimited
+// fields from each line, one output line per input line.
+func CutReader(delimiter string, fields []int, r io.Reader, w io.Writer) error {
+	sc := newLineScanner(r)
+	first := true
+	for sc.Scan() {
+		parts := strings.Split(sc.Text(), delimiter)
+		var selected []string
+		for _, field := range fields {
+			if field > 0 && field <= len(parts) {
+				selected = append(selected, parts[field-1])
+			}
+		}
+		if !first {
+			io.WriteString(w, "\n")
+		}
+		first = false
+		io.WriteString(w, strings.Join(selected, delimiter))
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	return nil
+}
+
+// SedReader streams lines from r to w, replacing every occurrence of old
+// with new on each line.
+func SedReader(old, new string, r io.Reader, w io.Writer) error {
+	sc := newLineScanner(r)
+	first := true
+	for sc.Scan() {
+		if !first {
+			io.WriteString(w, "\n")
+		}
+		first = false
+		io.WriteString(w, strings.ReplaceAll(sc.Text(), old, new))
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	return nil
+}
+
+// UniqReader streams lines from r to w, dropping a line if it is identical
+// to the one immediately before it.
+func UniqReader(r io.Reader, w io.Writer) error {
+	sc := newLineScanner(r)
+	first := true
+	var prev string
+	havePrev := false
+	for sc.Scan() {
+		line := sc.Text()
+		if havePrev && line == prev {
+			continue
+		}
+		if !first {
+			io.WriteString(w, "\n")
+		}
+		first = false
+		io.WriteString(w, line)
+		prev, havePrev = line, true
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	return nil
+}
+
+// HeadReader streams at most the first n lines of r to w, stopping as soon
+// as n lines have been read.
+func HeadReader(n int, r io.Reader, w io.Writer) error {
+	sc := newLineScanner(r)
+	first := true
+	for i := 0; i < n && sc.Scan(); i++ {
+		if !first {
+			io.WriteString(w, "\n")
+		}
+		first = false
+		io.WriteString(w, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	return nil
+}
+
+// TailReader streams r and writes the last n lines to w. It keeps only a
+// fixed-size ring buffer of n lines in memory, so it runs in bounded
+// memory regardless of how much input it reads.
+func TailReader(n int, r io.Reader, w io.Writer) error {
+	if n <= 0 {
+		return nil
+	}
+	sc := newLineScanner(r)
+	ring := make([]string, 0, n)
+	next := 0
+	seen := 0
+	for sc.Scan() {
+		line := sc.Text()
+		if len(ring) < n {
+			ring = append(ring, line)
+		} else {
+			ring[next] = line
+			next = (next + 1) % n
+		}
+		seen++
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	start := 0
+	if seen > n {
+		start = next
+	}
+	for i := 0; i < len(ring); i++ {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		io.WriteString(w, ring[(start+i)%len(ring)])
+	}
+	return nil
+}
+
+// WcReader streams r and writes "lines words chars" to w. Lines are
+// counted as newline characters (matching `wc -l`), so it agrees with the
+// string-based Wc even on input with no trailing newline.
+func WcReader(r io.Reader, w io.Writer) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	var lines, words, chars int
+	for {
+		chunk, err := br.ReadString('\n')
+		chars += len(chunk)
+		words += len(strings.Fields(chunk))
+		if strings.HasSuffix(chunk, "\n") {
+			lines++
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("%w: %v", ErrIO, err)
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%d %d %d", lines, words, chars); err != nil {
+		return fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	return nil
+}
+
+// SortOpts configures SortReader. A zero value sorts entirely in memory;
+// set SpillThreshold to bound memory use on inputs larger than RAM.
+type SortOpts struct {
+	// FS is used to write and read the temporary spill files. Defaults to
+	// DefaultFS.
+	FS FileSystem
+	// SpillThreshold is the number of lines buffered before a run is
+	// sorted and spilled to a temp file. 0 disables spilling and sorts
+	// entirely in memory.
+	SpillThreshold int
+}
+
+// SortReader sorts the lines of r and writes them to w, using the default
+// (in-memory) SortOpts. Use SortOpts.SortReader to spill to disk for
+// inputs larger than RAM.
+func SortReader(r io.Reader, w io.Writer) error {
+	return SortOpts{}.SortReader(r, w)
+}
+
+// SortReader sorts the lines of r and writes them to w. If o.SpillThreshold
+// is set, it performs an external merge sort: runs of that many lines are
+// sorted and spilled to temp files via o.FS, then merged with a k-way heap
+// merge so memory use stays bounded by SpillThreshold rather than the
+// total input size.
+func (o SortOpts) SortReader(r io.Reader, w io.Writer) error {
+	fs := o.FS
+	if fs == nil {
+		fs = DefaultFS
+	}
+	sc := newLineScanner(r)
+	var buf []string
+	var runs []string
+	spill := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		path, err := spillRun(fs, buf)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, path)
+		buf = buf[:0]
+		return nil
+	}
+	for sc.Scan() {
+		buf = append(buf, sc.Text())
+		if o.SpillThreshold > 0 && len(buf) >= o.SpillThreshold {
+			if err := spill(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrIO, err)
+	}
+
+	if len(runs) == 0 {
+		sort.Strings(buf)
+		return writeLines(w, buf)
+	}
+	if err := spill(); err != nil {
+		return err
+	}
+	defer func() {
+		for _, p := range runs {
+			fs.RemoveAll(p)
+		}
+	}()
+	return mergeRuns(fs, runs, w)
+}
+
+var spillRunCounter int64
+
+func spillRun(fs FileSystem, lines []string) (string, error) {
+	sorted := make([]string, len(lines))
+	copy(sorted, lines)
+	sort.Strings(sorted)
+	n := atomic.AddInt64(&spillRunCounter, 1)
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("bashlike-sort-%d-%d.run", os.Getpid(), n))
+	if err := fs.WriteFile(path, []byte(strings.Join(sorted, "\n")+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrIO, err)
+	}
+	return path, nil
+}
+
+// runItem is one candidate line in the k-way merge heap below.
+type runItem struct {
+	line string
+	run  int
+}
+
+type runHeap []runItem
+
+func (h runHeap) Len() int            { return len(h) }
+func (h runHeap) Less(i, j int) bool  { return h[i].line < h[j].line }
+func (h runHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x interface{}) { *h = append(*h, x.(runItem)) }
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+func mergeRuns(fs FileSystem, runs []string, w io.Writer) error {
+	scanners := make([]*bufio.Scanner, len(runs))
+	closers := make([]io.Closer, len(runs))
+	defer func() {
+		for _, c := range closers {
+			if c != nil {
+				c.Close()
+			}
+		}
+	}()
+	for i, path := range runs {
+		f, err := fs.Open(path)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrIO, err)
+		}
+		closers[i] = f
+		scanners[i] = newLineScanner(f)
+	}
+
+	h := &runHeap{}
+	heap.Init(h)
+	for i, sc := range scanners {
+		if sc.Scan() {
+			heap.Push(h, runItem{line: sc.Text(), run: i})
+		}
+	}
+	first := true
+	for h.Len() > 0 {
+		item := heap.Pop(h).(runItem)
+		if !first {
+			io.WriteString(w, "\n")
+		}
+		first = false
+		io.WriteString(w, item.line)
+		if scanners[item.run].Scan() {
+			heap.Push(h, runItem{line: scanners[item.run].Text(), run: item.run})
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterBuiltin("grep", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		if len(args) < 1 {
+			return fmt.Errorf("%w: grep requires a pattern", ErrInvalidArgument)
+		}
+		return GrepReader(args[0], stdin, stdout)
+	})
+	RegisterBuiltin("cut", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		delimiter, fields, err := parseCutArgs(args)
+		if err != nil {
+			return err
+		}
+		return CutReader(delimiter, fields, stdin, stdout)
+	})
+	RegisterBuiltin("sed", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		if len(args) < 1 {
+			return fmt.Errorf("%w: sed requires an expression", ErrInvalidArgument)
+		}
+		old, new, err := parseSedExpr(args[0])
+		if err != nil {
+			return err
+		}
+		return SedReader(old, new, stdin, stdout)
+	})
+	RegisterBuiltin("sort", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		return SortOpts{FS: FSFromContext(ctx)}.SortReader(stdin, stdout)
+	})
+	RegisterBuiltin("uniq", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		return UniqReader(stdin, stdout)
+	})
+	RegisterBuiltin("wc", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		return WcReader(stdin, stdout)
+	})
+	RegisterBuiltin("head", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		n, err := lineCountArg(args, 10)
+		if err != nil {
+			return err
+		}
+		return HeadReader(n, stdin, stdout)
+	})
+	RegisterBuiltin("tail", func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+		n, err := lineCountArg(args, 10)
+		if err != nil {
+			return err
+		}
+		return TailReader(n, stdin, stdout)
+	})
+}
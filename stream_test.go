@@ -0,0 +1,38 @@
+package bashlike
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestSortReaderConcurrentSpill exercises SortOpts.SortReader from multiple
+// goroutines with spilling enabled. Run with -race: before spillRunCounter
+// was made atomic, concurrent spillRun calls could pick colliding temp-file
+// names and clobber each other's runs.
+func TestSortReaderConcurrentSpill(t *testing.T) {
+	const lines = 50
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var in strings.Builder
+			for i := lines; i > 0; i-- {
+				in.WriteString(strings.Repeat("a", i%5+1))
+				in.WriteByte('\n')
+			}
+			var out strings.Builder
+			opts := SortOpts{SpillThreshold: 10}
+			if err := opts.SortReader(strings.NewReader(in.String()), &out); err != nil {
+				t.Errorf("SortReader: %v", err)
+				return
+			}
+			got := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+			if len(got) != lines {
+				t.Errorf("got %d lines, want %d", len(got), lines)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,176 @@
+package bashlike
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// XargsOpts configures XargsP.
+type XargsOpts struct {
+	// Parallelism is the number of worker goroutines. Defaults to
+	// runtime.NumCPU().
+	Parallelism int
+	// MaxArgs batches up to this many input items into a single command
+	// invocation, like `xargs -n`. Defaults to 1 (one item per command).
+	MaxArgs int
+	// Shard and Shards restrict execution to items whose hash mod Shards
+	// equals Shard, so a work set can be split across shard runners.
+	// Shards <= 0 disables sharding.
+	Shard  int
+	Shards int
+	// KeepGoing runs every batch regardless of earlier failures and
+	// reports all errors via MultiError. Otherwise the first error
+	// cancels remaining work.
+	KeepGoing bool
+	// OnResult, if set, is called as each batch completes.
+	OnResult func(item string, res *Result)
+}
+
+// ItemResult is the outcome of running one batch of items.
+type ItemResult struct {
+	Item   string
+	Result *Result
+	Err    error
+}
+
+// XargsResult is the aggregate outcome of an XargsP run.
+type XargsResult struct {
+	Results  []ItemResult
+	Duration time.Duration
+	Err      error
+}
+
+// MultiError aggregates the errors of an XargsP run in which more than one
+// batch failed. Without KeepGoing, these include the errors of batches
+// stopped by the cancellation that follows the first failure.
+type MultiError struct {
+	Errors []error
+}
+
+func (m *MultiError) Error() string {
+	msgs := make([]string, len(m.Errors))
+	for i, err := range m.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d item(s) failed: %s", len(m.Errors), strings.Join(msgs, "; "))
+}
+
+func (m *MultiError) Unwrap() []error { return m.Errors }
+
+// XargsP runs command once per batch of input items, in parallel, the way
+// `xargs -P` does. Batches are distributed over opts.Parallelism worker
+// goroutines fed by a channel; ctx cancellation (or the first failure, when
+// opts.KeepGoing is false) stops dispatching new batches but still returns
+// the results collected so far.
+func XargsP(ctx context.Context, input []string, opts XargsOpts, command string, args ...string) (*XargsResult, error) {
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = runtime.NumCPU()
+	}
+	batchSize := opts.MaxArgs
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	if opts.Shards > 0 {
+		input = shardItems(input, opts.Shard, opts.Shards)
+	}
+	batches := batchItems(input, batchSize)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan []string)
+	results := make(chan ItemResult, len(batches))
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				item := strings.Join(batch, " ")
+				if runCtx.Err() != nil {
+					results <- ItemResult{Item: item, Err: runCtx.Err()}
+					continue
+				}
+				cmdArgs := append(append([]string{}, args...), batch...)
+				res, err := Run(runCtx, command, cmdArgs...)
+				if opts.OnResult != nil {
+					opts.OnResult(item, res)
+				}
+				results <- ItemResult{Item: item, Result: res, Err: err}
+				if err != nil && !opts.KeepGoing {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, batch := range batches {
+			select {
+			case <-runCtx.Done():
+				return
+			case jobs <- batch:
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	start := time.Now()
+	xr := &XargsResult{}
+	var errs []error
+	for r := range results {
+		xr.Results = append(xr.Results, r)
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	xr.Duration = time.Since(start)
+
+	switch {
+	case len(errs) == 0:
+		return xr, nil
+	case len(errs) == 1:
+		xr.Err = errs[0]
+		return xr, errs[0]
+	default:
+		me := &MultiError{Errors: errs}
+		xr.Err = me
+		return xr, me
+	}
+}
+
+func batchItems(input []string, size int) [][]string {
+	var batches [][]string
+	for i := 0; i < len(input); i += size {
+		end := i + size
+		if end > len(input) {
+			end = len(input)
+		}
+		batches = append(batches, input[i:end])
+	}
+	return batches
+}
+
+func shardItems(input []string, shard, shards int) []string {
+	var out []string
+	for _, item := range input {
+		h := fnv.New32a()
+		h.Write([]byte(item))
+		if int(h.Sum32()%uint32(shards)) == shard {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package bashlike
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestXargsPSharding checks that Shard/Shards partition the input exactly
+// once across shards: every item runs in exactly one shard, and the shards
+// together cover the whole input with no duplicates.
+func TestXargsPSharding(t *testing.T) {
+	input := make([]string, 20)
+	for i := range input {
+		input[i] = strings.Repeat("x", i+1)
+	}
+	const shards = 4
+	seen := map[string]int{}
+	for shard := 0; shard < shards; shard++ {
+		xr, err := XargsP(context.Background(), input, XargsOpts{Shard: shard, Shards: shards}, "echo")
+		if err != nil {
+			t.Fatalf("shard %d: %v", shard, err)
+		}
+		for _, r := range xr.Results {
+			seen[r.Item]++
+		}
+	}
+	if len(seen) != len(input) {
+		t.Fatalf("sharding covered %d distinct items, want %d", len(seen), len(input))
+	}
+	for item, n := range seen {
+		if n != 1 {
+			t.Fatalf("item %q ran in %d shards, want exactly 1", item, n)
+		}
+	}
+}
+
+// TestXargsPKeepGoing checks that KeepGoing runs every batch regardless of
+// earlier failures and aggregates every error into a MultiError.
+func TestXargsPKeepGoing(t *testing.T) {
+	input := []string{"0", "1", "2", "3"}
+	xr, err := XargsP(context.Background(), input, XargsOpts{KeepGoing: true, MaxArgs: 1},
+		"sh", "-c", `case "$1" in 1|3) exit 1;; esac`, "_")
+	if err == nil {
+		t.Fatal("expected an aggregate error")
+	}
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("err = %v (%T), want *MultiError", err, err)
+	}
+	if len(multi.Errors) != 2 {
+		t.Fatalf("MultiError has %d errors, want 2", len(multi.Errors))
+	}
+	if len(xr.Results) != len(input) {
+		t.Fatalf("got %d results, want %d (KeepGoing must still run every batch)", len(xr.Results), len(input))
+	}
+}
+
+// TestXargsPMaxArgsBatching checks that MaxArgs groups multiple input items
+// into a single command invocation, like `xargs -n`.
+func TestXargsPMaxArgsBatching(t *testing.T) {
+	input := []string{"a", "b", "c", "d", "e"}
+	xr, err := XargsP(context.Background(), input, XargsOpts{MaxArgs: 2, Parallelism: 1}, "echo")
+	if err != nil {
+		t.Fatalf("XargsP: %v", err)
+	}
+	if len(xr.Results) != 3 {
+		t.Fatalf("got %d batches, want 3 (ceil(5/2))", len(xr.Results))
+	}
+	var items []string
+	for _, r := range xr.Results {
+		items = append(items, r.Item)
+	}
+	sort.Strings(items)
+	want := []string{"a b", "c d", "e"}
+	sort.Strings(want)
+	for i := range want {
+		if items[i] != want[i] {
+			t.Fatalf("batches = %v, want %v", items, want)
+		}
+	}
+}